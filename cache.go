@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// cacheEntry is the on-disk representation of a single cached repo.
+// PRNumbers is persisted (rather than a bare count) so that incremental
+// runs can fold in newly-seen PRs by number instead of re-adding ones the
+// search already returned before, e.g. because an old merged PR received a
+// comment and was returned again by the updated:>= narrowing.
+type cacheEntry struct {
+	Host      string    `json:"host"`
+	Owner     string    `json:"owner"`
+	Name      string    `json:"name"`
+	MergedAt  time.Time `json:"merged_at"`
+	PRNumbers []int     `json:"pr_numbers"`
+	User      string    `json:"user"`
+}
+
+// Cache persists merged-at timestamps and PR counts for previously
+// discovered repos between runs, along with the time of the last run, so
+// that subsequent invocations can ask GitHub only for what changed since
+// then instead of re-walking full search results every time.
+type Cache struct {
+	LastRun time.Time             `json:"last_run"`
+	Repos   map[string]cacheEntry `json:"repos"`
+}
+
+// cacheKey turns a Repo into the string key used in Cache.Repos.
+func cacheKey(repo Repo) string {
+	return repo.Host + "/" + repo.Owner + "/" + repo.Name
+}
+
+// loadCache reads the cache at path. A missing file (or empty path) yields
+// an empty, zero-LastRun cache rather than an error, so the first run of a
+// tool still works without one.
+func loadCache(path string) (Cache, error) {
+	if path == "" {
+		return Cache{Repos: map[string]cacheEntry{}}, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Cache{Repos: map[string]cacheEntry{}}, nil
+	}
+	if err != nil {
+		return Cache{}, err
+	}
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cache{}, err
+	}
+	if c.Repos == nil {
+		c.Repos = map[string]cacheEntry{}
+	}
+	return c, nil
+}
+
+// save writes the cache to path as JSON. It is a no-op when path is empty.
+func (c Cache) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// toMap returns the cached repos as a map[Repo]RepoRef, the same shape
+// findPRs works with in memory.
+func (c Cache) toMap() map[Repo]RepoRef {
+	m := make(map[Repo]RepoRef, len(c.Repos))
+	for _, e := range c.Repos {
+		repo := Repo{Host: e.Host, Owner: e.Owner, Name: e.Name}
+		numbers := make(map[int]struct{}, len(e.PRNumbers))
+		for _, n := range e.PRNumbers {
+			numbers[n] = struct{}{}
+		}
+		m[repo] = RepoRef{Repo: repo, MergedAt: e.MergedAt, PRNumbers: numbers, User: e.User}
+	}
+	return m
+}
+
+// cacheFromMap builds a Cache ready to be saved from the final merged map
+// and the LastRun time to record (main passes the run's start time only
+// when every target's search succeeded, and the previous LastRun otherwise).
+func cacheFromMap(m map[Repo]RepoRef, lastRun time.Time) Cache {
+	repos := make(map[string]cacheEntry, len(m))
+	for repo, ref := range m {
+		numbers := make([]int, 0, len(ref.PRNumbers))
+		for n := range ref.PRNumbers {
+			numbers = append(numbers, n)
+		}
+		repos[cacheKey(repo)] = cacheEntry{Host: repo.Host, Owner: repo.Owner, Name: repo.Name, MergedAt: ref.MergedAt, PRNumbers: numbers, User: ref.User}
+	}
+	return Cache{LastRun: lastRun, Repos: repos}
+}
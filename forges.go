@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// forgeTarget pairs a Source with the username to search for on it, since
+// the -forges flag lets each forge entry name a different user.
+type forgeTarget struct {
+	source Source
+	user   string
+}
+
+// parseForges parses the -forges flag value into a list of forge targets.
+// Entries are comma-separated "kind:arg" pairs, e.g.
+//
+//	github:torvalds,gitea:https://codeberg.org/someuser,forgejo:https://v8.next.forgejo.org/someuser
+//
+// For "github" the arg is a plain username. For "gitea"/"forgejo" the arg is
+// the user's profile URL: the host is the forge instance, and the last path
+// segment is the username.
+func parseForges(spec, token string, updatedSince, mergedSince time.Time) ([]forgeTarget, error) {
+	var targets []forgeTarget
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, arg, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid -forges entry %q: expected kind:arg", entry)
+		}
+		switch kind {
+		case "github":
+			targets = append(targets, forgeTarget{source: newGithubSource(token, updatedSince, mergedSince), user: arg})
+		case "gitea", "forgejo":
+			host, user, baseURL, err := splitForgeProfileURL(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -forges entry %q: %w", entry, err)
+			}
+			source, err := newGiteaSource(baseURL, host)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -forges entry %q: %w", entry, err)
+			}
+			targets = append(targets, forgeTarget{source: source, user: user})
+		default:
+			return nil, fmt.Errorf("invalid -forges entry %q: unknown forge kind %q", entry, kind)
+		}
+	}
+	return targets, nil
+}
+
+// splitForgeProfileURL splits a Gitea/Forgejo profile URL such as
+// "https://codeberg.org/someuser" into its host, username and base URL
+// (scheme://host).
+func splitForgeProfileURL(profileURL string) (host, user, baseURL string, err error) {
+	u, err := url.Parse(profileURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", "", fmt.Errorf("expected a full URL like https://host/user, got %q", profileURL)
+	}
+	path := strings.Trim(u.Path, "/")
+	if path == "" {
+		return "", "", "", fmt.Errorf("missing username in URL %q", profileURL)
+	}
+	parts := strings.Split(path, "/")
+	user = parts[len(parts)-1]
+	base := &url.URL{Scheme: u.Scheme, Host: u.Host}
+	return u.Host, user, base.String(), nil
+}
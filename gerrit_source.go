@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// gerritMagicPrefix is prepended to every Gerrit REST API JSON response as
+// an XSSI guard and must be stripped before parsing.
+var gerritMagicPrefix = []byte(")]}'\n")
+
+// gerritTimeLayout is the format Gerrit uses for timestamps in its REST API.
+const gerritTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// gerritPageSize is how many changes are requested per page.
+const gerritPageSize = 100
+
+// gerritChange is the subset of a Gerrit ChangeInfo this tool cares about.
+type gerritChange struct {
+	Number      int    `json:"_number"`
+	Project     string `json:"project"`
+	Submitted   string `json:"submitted"`
+	Status      string `json:"status"`
+	MoreChanges bool   `json:"_more_changes"`
+}
+
+// gerritSource finds merged changes authored by a user (identified by
+// email) on a single Gerrit host, via direct calls to its REST API.
+type gerritSource struct {
+	host   string
+	client *http.Client
+}
+
+// newGerritSource builds a Source for the Gerrit instance at host (e.g.
+// "go.googlesource.com").
+func newGerritSource(host string) *gerritSource {
+	return &gerritSource{host: host, client: http.DefaultClient}
+}
+
+// FindMergedPRs searches Gerrit for merged changes owned by email and
+// returns one RepoRef per project, with Owner/Name split from the
+// project's "parent/leaf" path.
+func (s *gerritSource) FindMergedPRs(ctx context.Context, email string) ([]RepoRef, error) {
+	found := make(map[Repo]RepoRef)
+	skip := 0
+
+	for {
+		changes, err := s.queryChanges(ctx, email, skip)
+		if err != nil {
+			return refsFromFound(found), err
+		}
+		if len(changes) == 0 {
+			break
+		}
+
+		for _, c := range changes {
+			if c.Status != "MERGED" {
+				continue
+			}
+			mergedAt, err := time.Parse(gerritTimeLayout, c.Submitted)
+			if err != nil {
+				continue
+			}
+			owner, name := splitGerritProject(c.Project)
+			repo := Repo{Host: s.host, Owner: owner, Name: name}
+			ref := found[repo]
+			ref.Repo = repo
+			ref.User = email
+			if ref.PRNumbers == nil {
+				ref.PRNumbers = make(map[int]struct{})
+			}
+			ref.PRNumbers[c.Number] = struct{}{}
+			if mergedAt.After(ref.MergedAt) {
+				ref.MergedAt = mergedAt
+			}
+			found[repo] = ref
+		}
+
+		if !changes[len(changes)-1].MoreChanges {
+			break
+		}
+		skip += len(changes)
+	}
+
+	return refsFromFound(found), nil
+}
+
+// queryChanges fetches one page of /changes/ results starting at skip.
+func (s *gerritSource) queryChanges(ctx context.Context, email string, skip int) ([]gerritChange, error) {
+	u := fmt.Sprintf(
+		"https://%s/changes/?q=%s&n=%d&S=%d",
+		s.host,
+		url.QueryEscape(fmt.Sprintf("owner:%s status:merged", email)),
+		gerritPageSize,
+		skip,
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gerrit query %s: %w", s.host, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gerrit query %s: status %d", s.host, resp.StatusCode)
+	}
+
+	body = bytes.TrimPrefix(body, gerritMagicPrefix)
+	var changes []gerritChange
+	if err := json.Unmarshal(body, &changes); err != nil {
+		return nil, fmt.Errorf("gerrit query %s: %w", s.host, err)
+	}
+	return changes, nil
+}
+
+// splitGerritProject splits a Gerrit project path, e.g. "golang/go" or
+// "deeper/nested/project", into its parent (owner) and leaf (name).
+func splitGerritProject(project string) (owner, name string) {
+	idx := strings.LastIndex(project, "/")
+	if idx < 0 {
+		return "", project
+	}
+	return project[:idx], project[idx+1:]
+}
+
+// parseGerritTargets parses repeated "-gerrit host=email" flag values into
+// forge targets.
+func parseGerritTargets(entries []string) ([]forgeTarget, error) {
+	var targets []forgeTarget
+	for _, entry := range entries {
+		host, email, ok := strings.Cut(entry, "=")
+		if !ok || host == "" || email == "" {
+			return nil, fmt.Errorf("invalid -gerrit entry %q: expected host=email", entry)
+		}
+		targets = append(targets, forgeTarget{source: newGerritSource(host), user: email})
+	}
+	return targets, nil
+}
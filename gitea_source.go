@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// giteaSource finds merged PRs authored by a user on a Gitea or Forgejo
+// instance (the two are API-compatible, so one implementation covers both).
+type giteaSource struct {
+	client *gitea.Client
+	host   string
+
+	posterID int64
+}
+
+// newGiteaSource builds a Source for the Gitea/Forgejo instance at baseURL.
+func newGiteaSource(baseURL, host string) (*giteaSource, error) {
+	client, err := gitea.NewClient(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("gitea.NewClient(%s): %w", baseURL, err)
+	}
+	return &giteaSource{client: client, host: host}, nil
+}
+
+// PosterID returns the numeric user id resolved by the most recent
+// FindMergedPRs call, for use when building "poster=<id>" links.
+func (s *giteaSource) PosterID() int64 {
+	return s.posterID
+}
+
+// FindMergedPRs searches the Gitea/Forgejo instance for merged PRs authored
+// by user and returns one RepoRef per repository.
+func (s *giteaSource) FindMergedPRs(ctx context.Context, user string) ([]RepoRef, error) {
+	info, _, err := s.client.GetUserInfo(user)
+	if err != nil {
+		return nil, fmt.Errorf("gitea GetUserInfo(%s): %w", user, err)
+	}
+	s.posterID = info.ID
+
+	found := make(map[Repo]RepoRef)
+	page := 1
+	for {
+		issues, _, err := s.client.ListIssues(gitea.ListIssueOption{
+			Type:        gitea.IssueTypePull,
+			State:       gitea.StateClosed,
+			CreatedBy:   user,
+			ListOptions: gitea.ListOptions{Page: page, PageSize: 50},
+		})
+		if err != nil {
+			return refsFromFound(found), fmt.Errorf("gitea ListIssues: %w", err)
+		}
+		if len(issues) == 0 {
+			break
+		}
+
+		for _, issue := range issues {
+			if issue.PullRequest == nil || issue.PullRequest.Merged == nil {
+				continue
+			}
+			if issue.Repository == nil {
+				continue
+			}
+			repo := Repo{Host: s.host, Owner: issue.Repository.Owner, Name: issue.Repository.Name}
+			mergedAt := *issue.PullRequest.Merged
+			ref := found[repo]
+			ref.Repo = repo
+			ref.User = user
+			if ref.PRNumbers == nil {
+				ref.PRNumbers = make(map[int]struct{})
+			}
+			ref.PRNumbers[int(issue.Index)] = struct{}{}
+			if mergedAt.After(ref.MergedAt) {
+				ref.MergedAt = mergedAt
+			}
+			found[repo] = ref
+		}
+
+		page++
+	}
+
+	return refsFromFound(found), nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+)
+
+// githubHost is the host used for Repos found via githubSource.
+const githubHost = "github.com"
+
+// githubSearchConsumers is how many goroutines normalize search results into
+// Repo records concurrently with the paginated Search.Issues calls.
+const githubSearchConsumers = 4
+
+// githubSource finds merged PRs authored by a user on github.com.
+type githubSource struct {
+	client *github.Client
+
+	// updatedSince, when non-zero, narrows the search to issues updated on
+	// or after this time, for incremental runs backed by a cache.
+	updatedSince time.Time
+
+	// mergedSince, when non-zero, narrows the search to issues merged on or
+	// after this time, for the user-facing -since flag.
+	mergedSince time.Time
+}
+
+// newGithubSource returns a Source backed by a GitHub client. If token is
+// non-empty, requests are authenticated, which raises the rate limit from
+// ~60/hour to ~5000/hour. updatedSince and mergedSince, if non-zero, narrow
+// the search to issues updated, respectively merged, on or after that time.
+func newGithubSource(token string, updatedSince, mergedSince time.Time) *githubSource {
+	var httpClient *http.Client
+	if token != "" {
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+		httpClient = oauth2.NewClient(context.Background(), ts)
+	}
+	return &githubSource{client: github.NewClient(httpClient), updatedSince: updatedSince, mergedSince: mergedSince}
+}
+
+// FindMergedPRs searches GitHub for merged PRs authored by user and returns
+// one RepoRef per repository, carrying the most recent merge time. One
+// goroutine paginates Search.Issues while githubSearchConsumers goroutines
+// normalize the results into Repo records concurrently, coordinated with
+// errgroup.WithContext so a failure on either side cancels the rest
+// cleanly. On error, the RepoRef slice collected so far is still returned
+// alongside it, so callers can fall back to partial results.
+func (s *githubSource) FindMergedPRs(ctx context.Context, user string) ([]RepoRef, error) {
+	query := fmt.Sprintf("is:pr author:%s is:merged", user)
+	if !s.updatedSince.IsZero() {
+		query += fmt.Sprintf(" updated:>=%s", s.updatedSince.Format("2006-01-02"))
+	}
+	if !s.mergedSince.IsZero() {
+		query += fmt.Sprintf(" merged:>=%s", s.mergedSince.Format("2006-01-02"))
+	}
+	opts := &github.SearchOptions{
+		Sort:        "updated",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var mu sync.Mutex
+	found := make(map[Repo]RepoRef)
+	issues := make(chan *github.Issue, 100)
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		defer close(issues)
+		for {
+			result, resp, err := s.client.Search.Issues(ctx, query, opts)
+			if err != nil {
+				if wait, ok := rateLimitWait(err); ok {
+					select {
+					case <-time.After(wait):
+						continue
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				return fmt.Errorf("Search.Issues error: %w", err)
+			}
+
+			for _, issue := range result.Issues {
+				select {
+				case issues <- issue:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			if resp.NextPage == 0 {
+				return nil
+			}
+			opts.ListOptions.Page = resp.NextPage
+		}
+	})
+
+	for i := 0; i < githubSearchConsumers; i++ {
+		g.Go(func() error {
+			for issue := range issues {
+				repoURL := issue.GetRepositoryURL()
+				if repoURL == "" {
+					continue
+				}
+				owner, name := ownerRepoFromAPIURL(repoURL)
+				if owner == "" || name == "" {
+					continue
+				}
+				repo := Repo{Host: githubHost, Owner: owner, Name: name}
+				mergedAt := issue.PullRequestLinks.MergedAt.Time
+
+				mu.Lock()
+				ref := found[repo]
+				ref.Repo = repo
+				ref.User = user
+				if ref.PRNumbers == nil {
+					ref.PRNumbers = make(map[int]struct{})
+				}
+				ref.PRNumbers[issue.GetNumber()] = struct{}{}
+				if mergedAt.After(ref.MergedAt) {
+					ref.MergedAt = mergedAt
+				}
+				found[repo] = ref
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	return refsFromFound(found), err
+}
+
+// rateLimitWait inspects err for a *github.RateLimitError or
+// *github.AbuseRateLimitError and, if found, returns how long to sleep
+// before retrying the same request.
+func rateLimitWait(err error) (time.Duration, bool) {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return time.Until(rateErr.Rate.Reset.Time), true
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return time.Minute, true
+	}
+	return 0, false
+}
+
+// ownerRepoFromAPIURL parses "https://api.github.com/repos/owner/repo" into owner, repo
+func ownerRepoFromAPIURL(apiURL string) (owner, repo string) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", ""
+	}
+	// path should be "/repos/owner/repo"
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) >= 3 && parts[0] == "repos" {
+		return parts[1], parts[2]
+	}
+	// sometimes API root might omit "repos", handle fallback
+	if len(parts) >= 2 {
+		return parts[0], parts[1]
+	}
+	return "", ""
+}
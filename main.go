@@ -1,12 +1,33 @@
-// Prlist updates a target file by replacing the content between
-// <!--START_SECTION:prlist--> and <!--END_SECTION:prlist--> with a list
-// of repositories that have merged PRs authored by a specified GitHub user.
+// Prlist updates a target file by replacing the content between a pair of
+// marker comments with a list of repositories that have merged PRs
+// authored by a given user.
 //
 // Usage (flags):
 // -file (string) target file to update (required)
-// -user (string) GitHub username to search PRs for (required)
-// -ignore (string) colon-separated ignore list (e.g. "org/*:*/repo")
-// -format (string) output format: "md" (default), "html", or "html-br"
+// -user (string) GitHub username to search PRs for; ignored if -forges is set
+// -forges (string) comma-separated list of forges to search instead of -user, e.g.
+//
+//	"github:torvalds,gitea:https://codeberg.org/someuser,forgejo:https://v8.next.forgejo.org/someuser"
+//
+// -ignore (string) colon-separated ignore list, entries are "owner/name"
+// (any forge) or "host/owner/name" (that forge only), e.g.
+// "org/*:*/repo:github.com/org/repo"
+// -gerrit (string, repeatable) "host=email" pair naming a Gerrit instance
+// and the email to search for, e.g. -gerrit go.googlesource.com=you@example.com
+// -format (string) built-in output format: "md" (default), "html", or "html-br"
+// -template (string) path to a text/template (or html/template, by .html extension)
+// to use instead of -format; executed with {User, Repos, GeneratedAt}
+// -marker (string) marker name to replace between, default "prlist", i.e.
+// <!--START_SECTION:prlist--> ... <!--END_SECTION:prlist-->
+// -sort (string) sort order: "recent" (default), "stars", "name" or "prcount"
+// -token (string) GitHub token for authenticated requests; falls back to the
+// GITHUB_TOKEN or GH_TOKEN environment variables
+// -cache (string) path to a JSON file used to persist merged-at timestamps
+// between runs, so subsequent runs only ask GitHub for what changed since
+// -since (string) only consider PRs merged in the last duration, e.g.
+// "365d", "720h"; narrows the GitHub search with a merged:>= qualifier
+// -allow-partial (bool) if a forge search fails partway through, warn and
+// still update the file with whatever was found instead of aborting
 //
 // Example:
 // go run . -file README.md -user torvalds -ignore "org/*:*/repo" -format md
@@ -18,272 +39,336 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"html/template"
 	"io"
 	"log"
-	"maps"
-	"net/url"
 	"os"
 	"regexp"
-	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v74/github"
 )
 
 func main() {
-	fileName, user, ignoreStr, format := getArgs()
+	args := getArgs()
 	ctx := context.Background()
-	block := IgnorFromString(ignoreStr)
-	found := findPRs(ctx, user, &block)
-	file, err := os.OpenFile(fileName, os.O_RDWR, 0777)
+	block := IgnorFromString(args.ignore)
+
+	cache, err := loadCache(args.cache)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
-	text := ""
-	switch format {
-	case "md":
-		text = reposToMd(user, found)
-	case "html":
-		text = reposToHTML(user, found)
-	case "html-br":
-		text = reposToBrHTML(user, found)
-	}
-	updateFile(file, text)
-}
 
-// getArgs parses CLI flags and returns file, user, ignore and format values.
-func getArgs() (string, string, string, string) {
-	file := flag.String("file", "", "target file")
-	user := flag.String("user", "", "github user")
-	ignore := flag.String("ignore", "", "repos to ignore")
-	format := flag.String("format", "md", "repos to ignore")
-	flag.Parse()
-	if *file == "" || *user == "" {
-		log.Fatal("Not all cli args are passed")
+	var mergedSince time.Time
+	if args.since != "" {
+		d, err := parseSinceDuration(args.since)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mergedSince = time.Now().Add(-d)
 	}
-	return *file, *user, *ignore, *format
-}
 
-// Repo identifies a GitHub repository by owner and name.
-type Repo struct {
-	Owner string
-	Name  string
-}
+	targets, err := buildTargets(args.user, args.forges, args.gerrit, args.token, cache.LastRun, mergedSince)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-// Ignore holds sets of owners, names or explicit repos that should be skipped.
-// It is constructed from the -ignore CLI flag.
-type Ignore struct {
-	owners map[string]struct{}
-	names  map[string]struct{}
-	repos  map[Repo]struct{}
-}
+	runStart := time.Now()
+	merged, posterIDs, gerritHosts, complete := findPRs(ctx, targets, &block, cache.toMap(), args.allowPartial)
 
-// IgnorFromString parses the ignore string into an Ignore structure. The
-// format is a colon-separated list of owner/name pairs. Use "owner/*" to
-// ignore all repos of an owner, "*/name" to ignore all repos with that name.
-func IgnorFromString(str string) Ignore {
-	owners := map[string]struct{}{}
-	names := map[string]struct{}{}
-	repos := map[Repo]struct{}{}
-	pairs := strings.Split(str, ":")
-	for _, pair := range pairs {
-		elems := strings.Split(pair, "/")
-		if len(elems) < 2 {
-			continue
-		}
-		owner := elems[0]
-		name := elems[1]
-		if owner == "" || name == "" {
-			continue
-		}
-		if owner == "*" {
-			names[name] = struct{}{}
-			continue
-		}
-		if name == "*" {
-			owners[owner] = struct{}{}
-			continue
-		}
-		repos[Repo{owner, name}] = struct{}{}
+	// Only advance LastRun on a fully successful run: if a target errored
+	// and we're only here because -allow-partial is set, the next run's
+	// updated:>=LastRun narrowing must still cover whatever this run
+	// didn't finish scanning.
+	lastRun := cache.LastRun
+	if complete {
+		lastRun = runStart
 	}
-	return Ignore{owners, names, repos}
-}
-
-// Match reports whether the given repo should be ignored.
-func (i *Ignore) Match(repo Repo) bool {
-	if i == nil {
-		return false
+	if err := cacheFromMap(merged, lastRun).save(args.cache); err != nil {
+		log.Fatal(err)
 	}
-	_, ok := i.repos[repo]
-	if ok {
-		return true
+
+	found := repoInfos(merged)
+	if client := githubClientFor(targets); client != nil {
+		enrichWithMeta(ctx, client, found)
 	}
-	_, ok = i.owners[repo.Owner]
-	if ok {
-		return true
+	sortRepoInfos(found, args.sort)
+
+	templateText, isHTML, err := resolveTemplate(args.format, args.templatePath)
+	if err != nil {
+		log.Fatal(err)
 	}
-	_, ok = i.names[repo.Name]
-	if ok {
-		return true
+	data := buildTemplateData(args.user, found, posterIDs, gerritHosts, runStart)
+	text, err := renderTemplate(templateText, isHTML, data, posterIDs, gerritHosts)
+	if err != nil {
+		log.Fatal(err)
 	}
-	return false
-}
 
-// updateFile replaces the content between markers <!--START_SECTION:prlist--> and
-// <!--END_SECTION:prlist--> in the provided file with the supplied text.
-func updateFile(file *os.File, text string) {
-	data, err := io.ReadAll(file)
+	file, err := os.OpenFile(args.file, os.O_RDWR, 0777)
 	if err != nil {
 		log.Fatal(err)
 	}
-	orig := string(data)
-	start := "<!--START_SECTION:prlist-->"
-	end := "<!--END_SECTION:prlist-->"
-	wrapped := start + "\n" + text + end + "\n"
+	defer file.Close()
+	updateFile(file, text, args.marker)
+}
 
-	if strings.Contains(orig, start) && strings.Contains(orig, end) {
-		re := regexp.MustCompile(`(?sm)^` + start + `.*?` + end)
-		result := re.ReplaceAllString(orig, wrapped)
-		file.Seek(0, 0)
-		file.Truncate(0)
-		_, err := io.Copy(file, bytes.NewBuffer([]byte(result)))
-		if err != nil {
-			log.Fatal(err)
-		}
-		return
+// cliArgs holds the parsed CLI flags.
+type cliArgs struct {
+	file         string
+	user         string
+	ignore       string
+	format       string
+	forges       string
+	gerrit       []string
+	templatePath string
+	marker       string
+	sort         string
+	token        string
+	cache        string
+	since        string
+	allowPartial bool
+}
+
+// stringListFlag implements flag.Value for a flag that can be repeated,
+// collecting each occurrence's value in order.
+type stringListFlag []string
+
+func (s *stringListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// getArgs parses CLI flags. token falls back to GITHUB_TOKEN then GH_TOKEN
+// when -token is not passed.
+func getArgs() cliArgs {
+	file := flag.String("file", "", "target file")
+	user := flag.String("user", "", "github user")
+	ignore := flag.String("ignore", "", `repos to ignore: colon-separated "owner/name" (any forge) or "host/owner/name" (one forge) entries`)
+	format := flag.String("format", "md", "built-in output format: md, html or html-br")
+	forges := flag.String("forges", "", "comma-separated forges to search, e.g. github:user,gitea:https://codeberg.org/user")
+	var gerrit stringListFlag
+	flag.Var(&gerrit, "gerrit", "gerrit host=email pair to search, repeatable")
+	templatePath := flag.String("template", "", "path to a template file to use instead of -format")
+	marker := flag.String("marker", "prlist", "marker name, e.g. <!--START_SECTION:marker-->")
+	sort := flag.String("sort", "recent", "sort order: recent, stars, name or prcount")
+	token := flag.String("token", "", "github token for authenticated requests (falls back to GITHUB_TOKEN/GH_TOKEN)")
+	cache := flag.String("cache", "", "path to a JSON cache file of merged-at timestamps, for incremental runs")
+	since := flag.String("since", "", `only consider PRs merged in the last duration, e.g. "365d" or "720h"`)
+	allowPartial := flag.Bool("allow-partial", false, "on a forge search error, warn and still update the file with whatever was found")
+	flag.Parse()
+	if *file == "" || (*user == "" && *forges == "") {
+		log.Fatal("Not all cli args are passed")
+	}
+	resolvedToken := *token
+	if resolvedToken == "" {
+		resolvedToken = cmp.Or(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN"))
+	}
+	return cliArgs{
+		file:         *file,
+		user:         *user,
+		ignore:       *ignore,
+		format:       *format,
+		forges:       *forges,
+		gerrit:       gerrit,
+		templatePath: *templatePath,
+		marker:       *marker,
+		sort:         *sort,
+		token:        resolvedToken,
+		cache:        *cache,
+		since:        *since,
+		allowPartial: *allowPartial,
 	}
 }
 
-// renderHTMLTemplate executes a small HTML template with helper functions.
-func renderHTMLTemplate(text, user string, repos []Repo) string {
-	funcMap := template.FuncMap{
-		"prlink": getLinkToPRs,
+// parseSinceDuration parses a duration string for -since. In addition to
+// Go's native units (e.g. "720h"), it accepts a "d" suffix for days (e.g.
+// "365d"), which time.ParseDuration doesn't support.
+func parseSinceDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid -since %q: %w", s, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
 	}
-	tmpl := template.Must(template.New("template").Funcs(funcMap).Parse(text))
-	buf := bytes.Buffer{}
-	err := tmpl.Execute(&buf, map[string]any{"user": user, "repos": repos})
+	d, err := time.ParseDuration(s)
 	if err != nil {
-		log.Fatal(err)
+		return 0, fmt.Errorf("invalid -since %q: %w", s, err)
 	}
-	return buf.String()
+	return d, nil
 }
 
-// reposToHTML returns a simple unordered list of repo links in HTML.
-func reposToHTML(user string, repos []Repo) string {
-	tmpl := "{{$user := .user}}<ul>\n{{ range .repos }}<li> <a href=\"{{prlink $user . }}\">{{ .Owner }}/{{ .Name }}</a> </li>\n{{ end }}</ul>"
-	return renderHTMLTemplate(tmpl, user, repos)
+// resolveTemplate returns the template text to execute and whether it
+// should be parsed as html/template. templatePath, if set, takes
+// precedence over format.
+func resolveTemplate(format, templatePath string) (text string, isHTML bool, err error) {
+	if templatePath != "" {
+		return loadTemplate(templatePath)
+	}
+	switch format {
+	case "md":
+		return mdTemplate, false, nil
+	case "html":
+		return htmlTemplate, true, nil
+	case "html-br":
+		return htmlBrTemplate, true, nil
+	default:
+		return "", false, fmt.Errorf("unknown -format %q", format)
+	}
 }
 
-// reposToBrHTML returns repo links separated by <br> tags.
-func reposToBrHTML(user string, repos []Repo) string {
-	tmpl := "{{$user := .user}}{{ range .repos }}<a href=\"{{prlink $user . }}\">{{ .Owner }}/{{ .Name }}</a> <br>\n{{ end }}"
-	return renderHTMLTemplate(tmpl, user, repos)
-}
+// buildTargets resolves the forges to search: -forges (falling back to a
+// single GitHub source using user if empty), plus any -gerrit entries.
+// updatedSince narrows GitHub sources to issues updated on or after that
+// time (driven by the cache), and mergedSince narrows them to issues merged
+// on or after that time (driven by the -since flag); both are no-ops when
+// zero.
+func buildTargets(user, forgesStr string, gerritEntries []string, token string, updatedSince, mergedSince time.Time) ([]forgeTarget, error) {
+	var targets []forgeTarget
+	if forgesStr == "" {
+		targets = []forgeTarget{{source: newGithubSource(token, updatedSince, mergedSince), user: user}}
+	} else {
+		forgeTargets, err := parseForges(forgesStr, token, updatedSince, mergedSince)
+		if err != nil {
+			return nil, err
+		}
+		targets = forgeTargets
+	}
 
-// reposToMd renders the repositories as Markdown list of links.
-func reposToMd(user string, repos []Repo) string {
-	list := ""
-	for _, repo := range repos {
-		list += fmt.Sprintf(
-			"- [%s/%s](%s)\n",
-			repo.Owner,
-			repo.Name,
-			getLinkToPRs(user, repo),
-		)
+	gerritTargets, err := parseGerritTargets(gerritEntries)
+	if err != nil {
+		return nil, err
 	}
-	return list
+	return append(targets, gerritTargets...), nil
 }
 
-// getLinkToPRs builds a GitHub search URL that lists PRs authored by user in repo.
-func getLinkToPRs(user string, repo Repo) string {
-	// e.g. https://github.com/rpgp/rpgp/pulls?q=is%3Apr%20author%3Aasciimoth
-	u := &url.URL{
-		Scheme: "https",
-		Host:   "github.com",
-		Path:   fmt.Sprintf("/%s/%s/pulls", repo.Owner, repo.Name),
+// githubClientFor returns the *github.Client of the first GitHub target, or
+// nil if none of the targets search GitHub. It's used to fetch repo
+// metadata after the PR search is done.
+func githubClientFor(targets []forgeTarget) *github.Client {
+	for _, target := range targets {
+		if s, ok := target.source.(*githubSource); ok {
+			return s.client
+		}
 	}
-	v := url.Values{}
-	v.Set("q", fmt.Sprintf("is:pr author:%s", user))
-	u.RawQuery = v.Encode()
-
-	return u.String()
+	return nil
 }
 
-// findPRs searches GitHub for merged PRs by the provided user and returns
-// a sorted list of unique repositories (most recently merged first). The
-// block parameter can be used to exclude certain repos/owners/names.
-func findPRs(ctx context.Context, user string, block *Ignore) []Repo {
-	client := github.NewClient(nil)
-	query := fmt.Sprintf("is:pr author:%s is:merged", user)
-	opts := &github.SearchOptions{
-		Sort:        "updated",
-		Order:       "desc",
-		ListOptions: github.ListOptions{PerPage: 100},
-	}
+// findPRs runs every forge target and folds the results into cached, a
+// map[Repo]RepoRef seeded with previously cached merged-at times and PR
+// counts. cached is filtered against block first, so a repo added to
+// -ignore after it was cached actually disappears from the output instead
+// of rolling forward forever. It returns the merged result, the numeric
+// poster id resolved for each Gitea/Forgejo host, the set of hosts that are
+// Gerrit instances (both needed when building links), and whether every
+// target's search succeeded. If a target's search fails partway through,
+// findPRs still folds in whatever it collected: with allowPartial it logs a
+// warning and carries on to the next target (but reports complete=false, so
+// the caller knows not to treat this as a fully-synced run), otherwise it's
+// fatal.
+func findPRs(ctx context.Context, targets []forgeTarget, block *Ignore, cached map[Repo]RepoRef, allowPartial bool) (merged map[Repo]RepoRef, posterIDs map[string]int64, gerritHosts map[string]struct{}, complete bool) {
+	posterIDs = make(map[string]int64)
+	gerritHosts = make(map[string]struct{})
+	complete = true
 
-	found := make(map[Repo]int64)
+	for repo := range cached {
+		if block.Match(repo) {
+			delete(cached, repo)
+		}
+	}
 
-	for {
-		result, resp, err := client.Search.Issues(ctx, query, opts)
+	for _, target := range targets {
+		refs, err := target.source.FindMergedPRs(ctx, target.user)
 		if err != nil {
-			log.Fatalf("Search.Issues error: %v", err)
+			if !allowPartial {
+				log.Fatalf("%s: %v", target.user, err)
+			}
+			log.Printf("warning: %s: %v (keeping partial results)", target.user, err)
+			complete = false
 		}
-
-		for _, issue := range result.Issues {
-			repoURL := issue.GetRepositoryURL()
-			if repoURL == "" {
+		for _, ref := range refs {
+			if block.Match(ref.Repo) {
 				continue
 			}
-			owner, name := ownerRepoFromAPIURL(repoURL)
-			if owner == "" || name == "" {
+			existing, ok := cached[ref.Repo]
+			if !ok {
+				cached[ref.Repo] = ref
 				continue
 			}
-			repo := Repo{owner, name}
-			if block.Match(repo) {
-				continue
+			if existing.PRNumbers == nil {
+				existing.PRNumbers = make(map[int]struct{})
 			}
-			time := issue.PullRequestLinks.MergedAt.Unix()
-			old, ok := found[repo]
-			if ok {
-				if time > old {
-					found[repo] = time
-				}
-			} else {
-				found[repo] = time
+			for n := range ref.PRNumbers {
+				existing.PRNumbers[n] = struct{}{}
 			}
+			existing.User = ref.User
+			if ref.MergedAt.After(existing.MergedAt) {
+				existing.MergedAt = ref.MergedAt
+			}
+			cached[ref.Repo] = existing
 		}
-
-		if resp.NextPage == 0 {
-			break
+		switch s := target.source.(type) {
+		case *giteaSource:
+			posterIDs[s.host] = s.PosterID()
+		case *gerritSource:
+			gerritHosts[s.host] = struct{}{}
 		}
-		opts.ListOptions.Page = resp.NextPage
 	}
 
-	sorted := slices.Collect(maps.Keys(found))
+	return cached, posterIDs, gerritHosts, complete
+}
 
-	slices.SortFunc(sorted, func(a, b Repo) int {
-		return cmp.Compare(found[b], found[a])
-	})
+// repoInfos turns the merged map into a []RepoInfo ready for metadata
+// enrichment, sorting and rendering.
+func repoInfos(merged map[Repo]RepoRef) []RepoInfo {
+	infos := make([]RepoInfo, 0, len(merged))
+	for repo, ref := range merged {
+		infos = append(infos, RepoInfo{Repo: repo, MergedAt: ref.MergedAt, PRCount: len(ref.PRNumbers), User: ref.User})
+	}
+	return infos
+}
 
-	return sorted
+// enrichWithMeta fetches RepoMeta for every github.com repo in infos and
+// fills it in, in place.
+func enrichWithMeta(ctx context.Context, client *github.Client, infos []RepoInfo) {
+	repos := make([]Repo, len(infos))
+	for i, info := range infos {
+		repos[i] = info.Repo
+	}
+	metas := fetchGithubMeta(ctx, client, repos)
+	for i, info := range infos {
+		if meta, ok := metas[info.Repo]; ok {
+			infos[i].Meta = meta
+		}
+	}
 }
 
-// ownerRepoFromAPIURL parses "https://api.github.com/repos/owner/repo" into owner, repo
-func ownerRepoFromAPIURL(apiURL string) (owner, repo string) {
-	u, err := url.Parse(apiURL)
+// updateFile replaces the content between the <!--START_SECTION:marker-->
+// and <!--END_SECTION:marker--> comments in the provided file with text.
+func updateFile(file *os.File, text, marker string) {
+	data, err := io.ReadAll(file)
 	if err != nil {
-		return "", ""
-	}
-	// path should be "/repos/owner/repo"
-	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
-	if len(parts) >= 3 && parts[0] == "repos" {
-		return parts[1], parts[2]
+		log.Fatal(err)
 	}
-	// sometimes API root might omit "repos", handle fallback
-	if len(parts) >= 2 {
-		return parts[0], parts[1]
+	orig := string(data)
+	start := "<!--START_SECTION:" + marker + "-->"
+	end := "<!--END_SECTION:" + marker + "-->"
+	wrapped := start + "\n" + text + end + "\n"
+
+	if strings.Contains(orig, start) && strings.Contains(orig, end) {
+		re := regexp.MustCompile(`(?sm)^` + regexp.QuoteMeta(start) + `.*?` + regexp.QuoteMeta(end))
+		result := re.ReplaceAllString(orig, wrapped)
+		file.Seek(0, 0)
+		file.Truncate(0)
+		_, err := io.Copy(file, bytes.NewBuffer([]byte(result)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
-	return "", ""
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// metaFetchWorkers bounds how many concurrent Repositories.Get calls are
+// made when enriching repos with metadata.
+const metaFetchWorkers = 8
+
+// fetchGithubMeta fetches RepoMeta for every repo on github.com, using a
+// bounded pool of workers. Repos on other hosts are left out of the result,
+// since the GitHub repos API only describes github.com repositories.
+func fetchGithubMeta(ctx context.Context, client *github.Client, repos []Repo) map[Repo]RepoMeta {
+	jobs := make(chan Repo)
+	results := make(map[Repo]RepoMeta)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < metaFetchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range jobs {
+				ghRepo, _, err := client.Repositories.Get(ctx, repo.Owner, repo.Name)
+				if err != nil {
+					log.Printf("fetch metadata for %s/%s: %v", repo.Owner, repo.Name, err)
+					continue
+				}
+				meta := RepoMeta{
+					Stars:         ghRepo.GetStargazersCount(),
+					Description:   ghRepo.GetDescription(),
+					Language:      ghRepo.GetLanguage(),
+					Archived:      ghRepo.GetArchived(),
+					DefaultBranch: ghRepo.GetDefaultBranch(),
+					HTMLURL:       ghRepo.GetHTMLURL(),
+				}
+				mu.Lock()
+				results[repo] = meta
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, repo := range repos {
+		if repo.Host == githubHost {
+			jobs <- repo
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// humanizeStars formats a star count the way GitHub does, e.g. 12345 -> "12.3k".
+func humanizeStars(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
+
+// sortRepoInfos sorts infos in place according to mode: "recent" (default,
+// most recently merged first), "stars", "name" or "prcount".
+func sortRepoInfos(infos []RepoInfo, mode string) {
+	var less func(a, b RepoInfo) int
+	switch mode {
+	case "stars":
+		less = func(a, b RepoInfo) int { return cmp.Compare(b.Meta.Stars, a.Meta.Stars) }
+	case "name":
+		less = func(a, b RepoInfo) int {
+			return cmp.Compare(a.Owner+"/"+a.Name, b.Owner+"/"+b.Name)
+		}
+	case "prcount":
+		less = func(a, b RepoInfo) int { return cmp.Compare(b.PRCount, a.PRCount) }
+	default:
+		less = func(a, b RepoInfo) int { return cmp.Compare(b.MergedAt.Unix(), a.MergedAt.Unix()) }
+	}
+	slices.SortFunc(infos, less)
+}
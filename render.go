@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// getLinkToPRs builds a URL that lists PRs authored by user in repo,
+// branching on which forge repo.Host belongs to. Gitea/Forgejo links need
+// the user's numeric id on that host, looked up from posterIDs (keyed by
+// host), since their poster filter is id-based rather than username-based.
+// gerritHosts marks which hosts are Gerrit instances, since Gerrit and
+// Gitea/Forgejo links can't otherwise be told apart from repo.Host alone.
+func getLinkToPRs(user string, repo Repo, posterIDs map[string]int64, gerritHosts map[string]struct{}) string {
+	if repo.Host == githubHost {
+		// e.g. https://github.com/rpgp/rpgp/pulls?q=is%3Apr+author%3Aasciimoth
+		u := &url.URL{
+			Scheme: "https",
+			Host:   githubHost,
+			Path:   fmt.Sprintf("/%s/%s/pulls", repo.Owner, repo.Name),
+		}
+		v := url.Values{}
+		v.Set("q", fmt.Sprintf("is:pr author:%s", user))
+		u.RawQuery = v.Encode()
+		return u.String()
+	}
+
+	if _, ok := gerritHosts[repo.Host]; ok {
+		// e.g. https://go.googlesource.com/q/owner:rsc@golang.org+status:merged+project:go
+		return fmt.Sprintf("https://%s/q/owner:%s+status:merged+project:%s", repo.Host, user, ownerSlashName(repo.Owner, repo.Name))
+	}
+
+	// Gitea/Forgejo: e.g.
+	// https://codeberg.org/owner/repo/pulls?poster=123&state=closed&type=all
+	u := &url.URL{
+		Scheme: "https",
+		Host:   repo.Host,
+		Path:   fmt.Sprintf("/%s/%s/pulls", repo.Owner, repo.Name),
+	}
+	v := url.Values{}
+	v.Set("type", "all")
+	v.Set("state", "closed")
+	if id, ok := posterIDs[repo.Host]; ok {
+		v.Set("poster", fmt.Sprintf("%d", id))
+	}
+	u.RawQuery = v.Encode()
+	return u.String()
+}
+
+// ownerSlashName joins owner and name as "owner/name" for display and for
+// Gerrit's "project:" qualifier, falling back to a bare name when owner is
+// empty (e.g. a top-level Gerrit project like "go" on go.googlesource.com,
+// which splitGerritProject returns with no owner).
+func ownerSlashName(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "/" + name
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Repo identifies a repository on a specific forge by host, owner and name.
+type Repo struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// RepoRef is a Repo together with the time its most recent matching PR was
+// merged, the set of matching PR/change numbers found (keyed by number so
+// that re-seeing the same PR across incremental runs doesn't inflate the
+// count), and the username (or, for Gerrit, email) that was searched for to
+// find it. Sources return RepoRefs; findPRs folds them down into a
+// deduplicated map[Repo]RepoRef, which repoInfos and sortRepoInfos then
+// turn into a sorted []RepoInfo.
+type RepoRef struct {
+	Repo
+	MergedAt  time.Time
+	PRNumbers map[int]struct{}
+	User      string
+}
+
+// Source finds merged PRs authored by user on a single forge and returns the
+// repositories they were merged into. On error, implementations still
+// return whatever RepoRefs were collected before the failure, so callers
+// can fall back to partial results instead of losing a run entirely.
+type Source interface {
+	FindMergedPRs(ctx context.Context, user string) ([]RepoRef, error)
+}
+
+// refsFromFound flattens a map[Repo]RepoRef accumulator into a slice, the
+// shape every Source implementation returns.
+func refsFromFound(found map[Repo]RepoRef) []RepoRef {
+	refs := make([]RepoRef, 0, len(found))
+	for _, ref := range found {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// RepoMeta carries repository details fetched separately from PR search,
+// e.g. via the GitHub repos API. It is left at its zero value for repos
+// whose forge doesn't support (or hasn't yet had) metadata fetched.
+type RepoMeta struct {
+	Stars         int
+	Description   string
+	Language      string
+	Archived      bool
+	DefaultBranch string
+	HTMLURL       string
+}
+
+// RepoInfo is a Repo enriched with everything rendering needs: when it was
+// last merged, how many PRs matched, which user/email found it (for
+// building its link), and (optionally) its metadata.
+type RepoInfo struct {
+	Repo
+	MergedAt time.Time
+	PRCount  int
+	User     string
+	Meta     RepoMeta
+}
+
+// hostOwner is a (host, owner) pair used by Ignore for owner-wildcard
+// entries. Host is "*" for entries parsed without one, matching any forge.
+type hostOwner struct {
+	Host  string
+	Owner string
+}
+
+// hostName is a (host, name) pair used by Ignore for name-wildcard entries.
+// Host is "*" for entries parsed without one, matching any forge.
+type hostName struct {
+	Host string
+	Name string
+}
+
+// hostOwnerName is a (host, owner, name) triple used by Ignore for explicit
+// repo entries. Host is "*" for entries parsed without one, matching any
+// forge.
+type hostOwnerName struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// Ignore holds sets of owners, names or explicit repos that should be
+// skipped, each scoped to a host (or "*" for any host). It is constructed
+// from the -ignore CLI flag.
+type Ignore struct {
+	owners map[hostOwner]struct{}
+	names  map[hostName]struct{}
+	repos  map[hostOwnerName]struct{}
+}
+
+// IgnorFromString parses the ignore string into an Ignore structure. The
+// format is a colon-separated list of entries, each either "owner/name"
+// (matches on any forge) or "host/owner/name" (matches only that host), e.g.
+// "org/*:*/repo:github.com/org/repo". Use "owner/*" to ignore all repos of
+// an owner, "*/name" to ignore all repos with that name; prefix either with
+// "host/" to scope the wildcard to one forge instead of all of them.
+func IgnorFromString(str string) Ignore {
+	owners := map[hostOwner]struct{}{}
+	names := map[hostName]struct{}{}
+	repos := map[hostOwnerName]struct{}{}
+	pairs := strings.Split(str, ":")
+	for _, pair := range pairs {
+		elems := strings.Split(pair, "/")
+		var host, owner, name string
+		switch len(elems) {
+		case 2:
+			host, owner, name = "*", elems[0], elems[1]
+		case 3:
+			host, owner, name = elems[0], elems[1], elems[2]
+		default:
+			continue
+		}
+		if host == "" || owner == "" || name == "" {
+			continue
+		}
+		if owner == "*" {
+			names[hostName{host, name}] = struct{}{}
+			continue
+		}
+		if name == "*" {
+			owners[hostOwner{host, owner}] = struct{}{}
+			continue
+		}
+		repos[hostOwnerName{host, owner, name}] = struct{}{}
+	}
+	return Ignore{owners, names, repos}
+}
+
+// Match reports whether the given repo should be ignored. Entries parsed
+// with an explicit host only match that host; entries parsed without one
+// match the owner/name pair on any forge.
+func (i *Ignore) Match(repo Repo) bool {
+	if i == nil {
+		return false
+	}
+	if _, ok := i.repos[hostOwnerName{repo.Host, repo.Owner, repo.Name}]; ok {
+		return true
+	}
+	if _, ok := i.repos[hostOwnerName{"*", repo.Owner, repo.Name}]; ok {
+		return true
+	}
+	if _, ok := i.owners[hostOwner{repo.Host, repo.Owner}]; ok {
+		return true
+	}
+	if _, ok := i.owners[hostOwner{"*", repo.Owner}]; ok {
+		return true
+	}
+	if _, ok := i.names[hostName{repo.Host, repo.Name}]; ok {
+		return true
+	}
+	if _, ok := i.names[hostName{"*", repo.Name}]; ok {
+		return true
+	}
+	return false
+}
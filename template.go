@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Built-in templates for -format md/html/html-br, used when -template is not
+// set. html and html-br render only the linked repo names; md also shows
+// stars, language and description for github.com repos.
+const (
+	mdTemplate = `{{ range .Repos }}- [{{ .DisplayName }}]({{ .Link }}){{ if eq .Host "github.com" }} — {{ stars .Stars }} ★{{ if .Language }} · {{ .Language }}{{ end }}{{ if .Description }} · "{{ .Description }}"{{ end }}{{ end }}
+{{ end }}`
+
+	htmlTemplate = `<ul>
+{{ range .Repos }}<li> <a href="{{ .Link }}">{{ .DisplayName }}</a> </li>
+{{ end }}</ul>`
+
+	htmlBrTemplate = `{{ range .Repos }}<a href="{{ .Link }}">{{ .DisplayName }}</a> <br>
+{{ end }}`
+)
+
+// templateRepo is the view of a repo exposed to user templates: everything
+// a template might want to show, with the PR link already resolved.
+type templateRepo struct {
+	Host        string
+	Owner       string
+	Name        string
+	DisplayName string
+	MergedAt    time.Time
+	Stars       int
+	Description string
+	Language    string
+	Archived    bool
+	PRCount     int
+	User        string
+	Link        string
+}
+
+// templateData is the root object executed against a template.
+type templateData struct {
+	User        string
+	Repos       []templateRepo
+	GeneratedAt time.Time
+}
+
+// buildTemplateData converts found repos into the shape templates see. Each
+// repo's link is built with the user/email that actually matched it, which
+// may differ per forge (see RepoInfo.User), not the top-level -user flag.
+func buildTemplateData(user string, found []RepoInfo, posterIDs map[string]int64, gerritHosts map[string]struct{}, generatedAt time.Time) templateData {
+	repos := make([]templateRepo, 0, len(found))
+	for _, info := range found {
+		repos = append(repos, templateRepo{
+			Host:        info.Host,
+			Owner:       info.Owner,
+			Name:        info.Name,
+			DisplayName: ownerSlashName(info.Owner, info.Name),
+			MergedAt:    info.MergedAt,
+			Stars:       info.Meta.Stars,
+			Description: info.Meta.Description,
+			Language:    info.Meta.Language,
+			Archived:    info.Meta.Archived,
+			PRCount:     info.PRCount,
+			User:        info.User,
+			Link:        getLinkToPRs(info.User, info.Repo, posterIDs, gerritHosts),
+		})
+	}
+	return templateData{User: user, Repos: repos, GeneratedAt: generatedAt}
+}
+
+// templateFuncs builds the FuncMap shared by every template, built-in or
+// user-supplied.
+func templateFuncs(posterIDs map[string]int64, gerritHosts map[string]struct{}) map[string]any {
+	return map[string]any{
+		"prlink": func(user string, repo templateRepo) string {
+			return getLinkToPRs(user, Repo{Host: repo.Host, Owner: repo.Owner, Name: repo.Name}, posterIDs, gerritHosts)
+		},
+		"stars":          humanizeStars,
+		"markdownEscape": markdownEscape,
+		"humanizeTime":   humanizeTime,
+		"shortDesc":      shortDesc,
+		"displayName":    ownerSlashName,
+	}
+}
+
+// markdownEscape escapes characters with special meaning in Markdown.
+func markdownEscape(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		"*", `\*`,
+		"_", `\_`,
+		"[", `\[`,
+		"]", `\]`,
+		"`", "\\`",
+	)
+	return replacer.Replace(s)
+}
+
+// humanizeTime renders t as a short relative duration, e.g. "3 days ago".
+func humanizeTime(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d minutes ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%d months ago", int(d.Hours()/24/30))
+	default:
+		return fmt.Sprintf("%d years ago", int(d.Hours()/24/365))
+	}
+}
+
+// shortDesc truncates s to a sensible length for inline display.
+func shortDesc(s string) string {
+	const max = 80
+	if len(s) <= max {
+		return s
+	}
+	return strings.TrimSpace(s[:max]) + "…"
+}
+
+// loadTemplate reads the template at path. Files ending in .html/.htm are
+// parsed as html/template (auto-escaped); everything else (.gotmpl, .tmpl,
+// .txt, ...) is parsed as text/template.
+func loadTemplate(path string) (text string, isHTML bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	isHTML = ext == ".html" || ext == ".htm"
+	return string(data), isHTML, nil
+}
+
+// renderTemplate parses text with the engine matching isHTML and executes
+// it against data.
+func renderTemplate(text string, isHTML bool, data templateData, posterIDs map[string]int64, gerritHosts map[string]struct{}) (string, error) {
+	funcs := templateFuncs(posterIDs, gerritHosts)
+	buf := &bytes.Buffer{}
+	if isHTML {
+		tmpl, err := htmltemplate.New("prlist").Funcs(funcs).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		if err := tmpl.Execute(buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	tmpl, err := texttemplate.New("prlist").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}